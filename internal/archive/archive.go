@@ -0,0 +1,345 @@
+/*
+Package archive extracts wordlist downloads regardless of their container
+format.
+
+It replaces the old ad-hoc gzip-then-maybe-tar chain that used to live in
+wordlistctl.go with a single dispatcher that sniffs the file and extracts
+it safely, rejecting any entry that would escape the destination directory
+(the "Zip Slip" vulnerability).
+*/
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/h2non/filetype"
+	"github.com/ulikunitz/xz"
+)
+
+// ErrUnsafePath is returned when an archive entry (or a symlink target)
+// would resolve to a path outside of the requested destination directory.
+var ErrUnsafePath = errors.New("archive: entry escapes destination directory")
+
+// Extract sniffs archivePath and extracts its contents into destDir,
+// returning the list of files it wrote. It supports zip, 7z, tar+gzip,
+// tar+bzip2, tar+xz, and plain gzip/bzip2/xz single-file streams.
+//
+// Every entry is validated so that filepath.Clean(filepath.Join(destDir, name))
+// still begins with filepath.Clean(destDir)+string(os.PathSeparator); entries
+// that fail this check (including symlinks whose target escapes destDir) are
+// skipped and reported via ErrUnsafePath. Everything is streamed: archives
+// are never loaded whole into memory.
+func Extract(archivePath, destDir string) ([]string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 261)
+	n, err := f.Read(head)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case filetype.Is(head, "zip"):
+		return extractZip(archivePath, destDir)
+	case filetype.Is(head, "7z"):
+		return extract7z(archivePath, destDir)
+	case filetype.Is(head, "gz"):
+		return extractCompressedStream(f, gzipReaderNoError, destDir, archivePath)
+	case isBzip2(head):
+		return extractCompressedStream(f, bzip2ReaderNoError, destDir, archivePath)
+	case isXz(head):
+		return extractCompressedStream(f, xzReaderNoError, destDir, archivePath)
+	default:
+		return nil, fmt.Errorf("archive: unrecognized container for %s", archivePath)
+	}
+}
+
+// extractCompressedStream decompresses a single-layer stream (gzip, bzip2,
+// xz) and then checks whether the decompressed payload is itself a tarball;
+// if not, it is written out as a single file named after the original
+// archive with its compression suffix stripped.
+func extractCompressedStream(f *os.File, newReader func(io.Reader) (io.Reader, error), destDir, archivePath string) ([]string, error) {
+	decompressed, err := newReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufferedPeek(decompressed)
+	if filetype.Is(buffered.peeked, "tar") {
+		return extractTar(buffered, destDir)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(archivePath), filepath.Ext(archivePath))
+	target, err := safeJoin(destDir, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, buffered); err != nil {
+		return nil, err
+	}
+	return []string{target}, nil
+}
+
+func extractZip(archivePath, destDir string) ([]string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var written []string
+	for _, entry := range r.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if errors.Is(err, ErrUnsafePath) {
+			fmt.Fprintf(os.Stderr, "[ERROR]: skipping unsafe zip entry %q: %v\n", entry.Name, err)
+			continue
+		} else if err != nil {
+			return written, err
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return written, err
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return written, err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, entry.Mode())
+		if err != nil {
+			rc.Close()
+			return written, err
+		}
+		_, err = io.Copy(out, rc)
+		rc.Close()
+		out.Close()
+		if err != nil {
+			return written, err
+		}
+		os.Chtimes(target, entry.Modified, entry.Modified)
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+func extract7z(archivePath, destDir string) ([]string, error) {
+	r, err := sevenzip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var written []string
+	for _, entry := range r.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if errors.Is(err, ErrUnsafePath) {
+			fmt.Fprintf(os.Stderr, "[ERROR]: skipping unsafe 7z entry %q: %v\n", entry.Name, err)
+			continue
+		} else if err != nil {
+			return written, err
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return written, err
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return written, err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, entry.Mode())
+		if err != nil {
+			rc.Close()
+			return written, err
+		}
+		_, err = io.Copy(out, rc)
+		rc.Close()
+		out.Close()
+		if err != nil {
+			return written, err
+		}
+		os.Chtimes(target, entry.Modified, entry.Modified)
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+func extractTar(r io.Reader, destDir string) ([]string, error) {
+	var written []string
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return written, err
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if errors.Is(err, ErrUnsafePath) {
+			fmt.Fprintf(os.Stderr, "[ERROR]: skipping unsafe tar entry %q: %v\n", header.Name, err)
+			continue
+		} else if err != nil {
+			return written, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+				return written, err
+			}
+			os.Chmod(target, os.FileMode(header.Mode))
+			os.Chtimes(target, header.AccessTime, header.ModTime)
+
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return written, err
+			}
+			w, err := os.Create(target)
+			if err != nil {
+				return written, err
+			}
+			_, err = io.Copy(w, tr)
+			w.Close()
+			if err != nil {
+				return written, err
+			}
+			os.Chmod(target, os.FileMode(header.Mode))
+			os.Chtimes(target, header.AccessTime, header.ModTime)
+			written = append(written, target)
+
+		case tar.TypeSymlink, tar.TypeLink:
+			linkTarget := header.Linkname
+			if !filepath.IsAbs(linkTarget) {
+				linkTarget = filepath.Join(filepath.Dir(target), linkTarget)
+			}
+			if _, err := safeJoin(destDir, mustRel(destDir, linkTarget)); errors.Is(err, ErrUnsafePath) {
+				fmt.Fprintf(os.Stderr, "[ERROR]: skipping tar link %q escaping destination: %v\n", header.Name, err)
+				continue
+			}
+			if header.Typeflag == tar.TypeSymlink {
+				if err := os.Symlink(header.Linkname, target); err != nil {
+					return written, err
+				}
+			} else {
+				if err := os.Link(linkTarget, target); err != nil {
+					return written, err
+				}
+			}
+
+		default:
+			fmt.Fprintf(os.Stderr, "[ERROR]: unsupported tar entry type %v for %q\n", header.Typeflag, header.Name)
+		}
+	}
+	return written, nil
+}
+
+// safeJoin joins destDir and name, rejecting the result unless it still
+// lives inside destDir. This is what stops Zip Slip: an entry name like
+// "../../etc/passwd" would otherwise escape the extraction directory.
+func safeJoin(destDir, name string) (string, error) {
+	cleanDest := filepath.Clean(destDir)
+	target := filepath.Clean(filepath.Join(cleanDest, name))
+	if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return target, nil
+}
+
+// mustRel returns a path relative to nothing in particular; it exists only
+// to let symlink targets be re-validated through safeJoin using the same
+// logic as regular entries.
+func mustRel(destDir, linkTarget string) string {
+	rel, err := filepath.Rel(destDir, linkTarget)
+	if err != nil {
+		return linkTarget
+	}
+	return rel
+}
+
+func isBzip2(head []byte) bool {
+	return len(head) >= 3 && head[0] == 'B' && head[1] == 'Z' && head[2] == 'h'
+}
+
+func isXz(head []byte) bool {
+	magic := []byte{0xFD, '7', 'z', 'X', 'Z', 0x00}
+	return len(head) >= len(magic) && string(head[:len(magic)]) == string(magic)
+}
+
+func gzipReaderNoError(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+func bzip2ReaderNoError(r io.Reader) (io.Reader, error) {
+	return bzip2.NewReader(r), nil
+}
+
+func xzReaderNoError(r io.Reader) (io.Reader, error) {
+	return xz.NewReader(r)
+}
+
+// peekReader lets us sniff the start of a decompressed stream (to tell a
+// bare gzip/bzip2/xz payload from a tarball inside one) without losing the
+// bytes we already consumed.
+type peekReader struct {
+	peeked []byte
+	rest   io.Reader
+}
+
+func (p *peekReader) Read(b []byte) (int, error) {
+	if len(p.peeked) > 0 {
+		n := copy(b, p.peeked)
+		p.peeked = p.peeked[n:]
+		return n, nil
+	}
+	return p.rest.Read(b)
+}
+
+func bufferedPeek(r io.Reader) *peekReader {
+	// filetype's Tar matcher reads buf[261], so it needs at least 262
+	// bytes to ever return true; 261 would silently never match.
+	buf := make([]byte, 262)
+	n, _ := io.ReadFull(r, buf)
+	return &peekReader{peeked: buf[:n], rest: r}
+}
@@ -0,0 +1,293 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+func TestExtractZipSlipIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "evil.zip")
+	destDir := filepath.Join(dir, "dest")
+
+	writeZip(t, archivePath, map[string]string{
+		"../escaped.txt": "pwned",
+		"safe.txt":       "ok",
+	})
+
+	written, err := Extract(archivePath, destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escaped.txt")); !os.IsNotExist(err) {
+		t.Fatalf("zip-slip entry escaped destDir: got err=%v", err)
+	}
+
+	safePath := filepath.Join(destDir, "safe.txt")
+	if _, err := os.Stat(safePath); err != nil {
+		t.Fatalf("expected safe.txt to be extracted: %v", err)
+	}
+
+	found := false
+	for _, w := range written {
+		if w == safePath {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q in written list, got %v", safePath, written)
+	}
+}
+
+func TestExtractPlainGzip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "wordlist.txt.gz")
+	destDir := filepath.Join(dir, "dest")
+
+	writeGzip(t, archivePath, "password\nadmin\n")
+
+	written, err := Extract(archivePath, destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("expected exactly one extracted file, got %v", written)
+	}
+
+	contents, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatalf("couldn't read extracted file: %v", err)
+	}
+	if string(contents) != "password\nadmin\n" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+}
+
+func TestExtractTarGzip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "wordlist.tar.gz")
+	destDir := filepath.Join(dir, "dest")
+
+	writeTarGzip(t, archivePath, map[string]string{"wordlist.txt": "admin\npassword\n"})
+
+	written, err := Extract(archivePath, destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("expected exactly one extracted file, got %v", written)
+	}
+
+	contents, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatalf("couldn't read extracted file: %v", err)
+	}
+	if string(contents) != "admin\npassword\n" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+}
+
+func TestExtractTarBzip2(t *testing.T) {
+	destDir := filepath.Join(t.TempDir(), "dest")
+
+	written, err := Extract(filepath.Join("testdata", "sample.tar.bz2"), destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("expected exactly one extracted file, got %v", written)
+	}
+
+	contents, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatalf("couldn't read extracted file: %v", err)
+	}
+	if string(contents) != "admin\npassword\n" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+}
+
+func TestExtractTarXz(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "wordlist.tar.xz")
+	destDir := filepath.Join(dir, "dest")
+
+	writeTarXz(t, archivePath, map[string]string{"wordlist.txt": "admin\npassword\n"})
+
+	written, err := Extract(archivePath, destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("expected exactly one extracted file, got %v", written)
+	}
+
+	contents, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatalf("couldn't read extracted file: %v", err)
+	}
+	if string(contents) != "admin\npassword\n" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+}
+
+func TestExtractTarSkipsEscapingSymlink(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "evil.tar.gz")
+	destDir := filepath.Join(dir, "dest")
+
+	writeTarGzipWithSymlink(t, archivePath, "escape", "../../etc/passwd")
+
+	written, err := Extract(archivePath, destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+	if len(written) != 0 {
+		t.Fatalf("expected nothing to be written for an escaping symlink, got %v", written)
+	}
+	if _, err := os.Lstat(filepath.Join(destDir, "escape")); !os.IsNotExist(err) {
+		t.Fatalf("expected the escaping symlink to be skipped, got err=%v", err)
+	}
+}
+
+func TestExtract7z(t *testing.T) {
+	destDir := filepath.Join(t.TempDir(), "dest")
+
+	written, err := Extract(filepath.Join("testdata", "sample.7z"), destDir)
+	if err != nil {
+		t.Fatalf("Extract returned an error: %v", err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("expected two extracted files, got %v", written)
+	}
+
+	contents, err := os.ReadFile(filepath.Join(destDir, "large"))
+	if err != nil {
+		t.Fatalf("couldn't read extracted file: %v", err)
+	}
+	if string(contents) != "Huuuuge file contents" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "empty")); err != nil {
+		t.Fatalf("expected the empty file to be extracted too: %v", err)
+	}
+}
+
+func writeZip(t *testing.T, archivePath string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("creating zip entry %q: %v", name, err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatalf("writing zip entry %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip writer: %v", err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+}
+
+func writeTarGzip(t *testing.T, archivePath string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, contents := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(contents)), Mode: 0644}); err != nil {
+			t.Fatalf("writing tar header for %q: %v", name, err)
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatalf("writing tar contents for %q: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("closing gzip writer: %v", err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+}
+
+func writeTarGzipWithSymlink(t *testing.T, archivePath, linkName, linkTarget string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     linkName,
+		Linkname: linkTarget,
+		Typeflag: tar.TypeSymlink,
+		Mode:     0777,
+	}); err != nil {
+		t.Fatalf("writing symlink tar header: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("closing gzip writer: %v", err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+}
+
+func writeTarXz(t *testing.T, archivePath string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("creating xz writer: %v", err)
+	}
+	tw := tar.NewWriter(xw)
+	for name, contents := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(contents)), Mode: 0644}); err != nil {
+			t.Fatalf("writing tar header for %q: %v", name, err)
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatalf("writing tar contents for %q: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatalf("closing xz writer: %v", err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+}
+
+func writeGzip(t *testing.T, archivePath string, contents string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write([]byte(contents)); err != nil {
+		t.Fatalf("writing gzip contents: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("closing gzip writer: %v", err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+}
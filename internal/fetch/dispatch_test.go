@@ -0,0 +1,185 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// recordingFetcher remembers the rawURL it was asked to fetch, so tests can
+// assert on how Fetch resolved and rewrote it.
+type recordingFetcher struct {
+	gotURL string
+	err    error
+}
+
+func (f *recordingFetcher) Fetch(ctx context.Context, rawURL, dst string) error {
+	f.gotURL = rawURL
+	return f.err
+}
+
+// verifyingFetcher additionally implements DigestVerifier, to test that
+// FetchVerified prefers it over the plain Fetch path.
+type verifyingFetcher struct {
+	recordingFetcher
+	gotDigest string
+}
+
+func (f *verifyingFetcher) FetchVerified(ctx context.Context, rawURL, dst, expectedSHA256 string) error {
+	f.gotURL = rawURL
+	f.gotDigest = expectedSHA256
+	return f.err
+}
+
+func TestFetchDispatchesByScheme(t *testing.T) {
+	rec := &recordingFetcher{}
+	Register("test-scheme", rec)
+
+	if err := Fetch(context.Background(), "test-scheme://example/path", "/tmp/dst"); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if rec.gotURL != "test-scheme://example/path" {
+		t.Fatalf("expected fetcher to receive the full url, got %q", rec.gotURL)
+	}
+}
+
+func TestFetchUsesForcedScheme(t *testing.T) {
+	rec := &recordingFetcher{}
+	Register("forced", rec)
+
+	if err := Fetch(context.Background(), "forced::https://example.com/repo", "/tmp/dst"); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if rec.gotURL != "https://example.com/repo" {
+		t.Fatalf("expected the forced prefix to be stripped, got %q", rec.gotURL)
+	}
+}
+
+func TestFetchDispatchesBareMagnetLinks(t *testing.T) {
+	rec := &recordingFetcher{}
+	Register("magnet", rec)
+
+	magnet := "magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a&dn=rockyou.txt"
+	if err := Fetch(context.Background(), magnet, "/tmp/dst"); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if rec.gotURL != magnet {
+		t.Fatalf("expected the full magnet link to reach the fetcher unchanged, got %q", rec.gotURL)
+	}
+}
+
+func TestFetchUnknownSchemeErrors(t *testing.T) {
+	err := Fetch(context.Background(), "bogus://example/path", "/tmp/dst")
+	if err == nil {
+		t.Fatal("expected an error for an unregistered scheme")
+	}
+}
+
+func TestFetchPropagatesFetcherError(t *testing.T) {
+	rec := &recordingFetcher{err: errors.New("boom")}
+	Register("erroring", rec)
+
+	if err := Fetch(context.Background(), "erroring://x", "/tmp/dst"); err == nil {
+		t.Fatal("expected Fetch to propagate the fetcher's error")
+	}
+}
+
+func TestFetchVerifiedUsesDigestVerifierWhenAvailable(t *testing.T) {
+	vf := &verifyingFetcher{}
+	Register("verifying", vf)
+
+	if err := FetchVerified(context.Background(), "verifying://x", "/tmp/dst", "deadbeef"); err != nil {
+		t.Fatalf("FetchVerified: %v", err)
+	}
+	if vf.gotDigest != "deadbeef" {
+		t.Fatalf("expected the digest to reach FetchVerified, got %q", vf.gotDigest)
+	}
+}
+
+func TestFetchVerifiedFallsBackToPlainFetch(t *testing.T) {
+	rec := &recordingFetcher{}
+	Register("plain", rec)
+
+	if err := FetchVerified(context.Background(), "plain://x", "/tmp/dst", "deadbeef"); err != nil {
+		t.Fatalf("FetchVerified: %v", err)
+	}
+	if rec.gotURL != "plain://x" {
+		t.Fatalf("expected the fetcher to still receive the url, got %q", rec.gotURL)
+	}
+}
+
+func TestSupportsVerification(t *testing.T) {
+	Register("verifying2", &verifyingFetcher{})
+	Register("plain2", &recordingFetcher{})
+
+	if !SupportsVerification("verifying2://x") {
+		t.Error("expected verifying2 scheme to support verification")
+	}
+	if SupportsVerification("plain2://x") {
+		t.Error("expected plain2 scheme to not support verification")
+	}
+	if SupportsVerification("bogus://x") {
+		t.Error("expected an unregistered scheme to report no support")
+	}
+}
+
+func TestSplitForcedScheme(t *testing.T) {
+	cases := []struct {
+		in         string
+		wantScheme string
+		wantRest   string
+	}{
+		{"git::https://github.com/org/repo", "git", "https://github.com/org/repo"},
+		{"https://example.com/file", "", "https://example.com/file"},
+		{"magnet:?xt=urn:btih:HASH", "", "magnet:?xt=urn:btih:HASH"},
+	}
+	for _, c := range cases {
+		scheme, rest := splitForcedScheme(c.in)
+		if scheme != c.wantScheme || rest != c.wantRest {
+			t.Errorf("splitForcedScheme(%q) = (%q, %q), want (%q, %q)", c.in, scheme, rest, c.wantScheme, c.wantRest)
+		}
+	}
+}
+
+func TestURLScheme(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"https://example.com/file", "https"},
+		{"s3://bucket/key", "s3"},
+		{"magnet:?xt=urn:btih:HASH", "magnet"},
+		{"no-scheme-here", ""},
+	}
+	for _, c := range cases {
+		if got := urlScheme(c.in); got != c.want {
+			t.Errorf("urlScheme(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseS3URL(t *testing.T) {
+	bucket, key, err := parseS3URL("s3://my-bucket/path/to/wordlist.txt")
+	if err != nil {
+		t.Fatalf("parseS3URL: %v", err)
+	}
+	if bucket != "my-bucket" || key != "path/to/wordlist.txt" {
+		t.Fatalf("got bucket=%q key=%q, want bucket=%q key=%q", bucket, key, "my-bucket", "path/to/wordlist.txt")
+	}
+
+	if _, _, err := parseS3URL("s3://missing-key"); err == nil {
+		t.Fatal("expected an error for a url with no key")
+	}
+}
+
+func TestParseGitURL(t *testing.T) {
+	repoURL, subpath, ref := parseGitURL("https://github.com/org/repo//subdir?ref=v1.2.3")
+	if repoURL != "https://github.com/org/repo" || subpath != "subdir" || ref != "v1.2.3" {
+		t.Fatalf("got (%q, %q, %q)", repoURL, subpath, ref)
+	}
+
+	repoURL, subpath, ref = parseGitURL("https://github.com/org/repo")
+	if repoURL != "https://github.com/org/repo" || subpath != "" || ref != "" {
+		t.Fatalf("got (%q, %q, %q), want no subpath/ref", repoURL, subpath, ref)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Package fetch dispatches a wordlist download to the right backend based on
+the URL's scheme, go-getter style: "http(s)://", "git::<url>", "s3://",
+"file://" and "torrent://"/magnet links are all handled by a Fetcher
+registered under that scheme, so archive.json entries can point at any of
+them without wordlistctl needing new code per source.
+*/
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Fetcher retrieves whatever rawURL points at and places it at dst.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL, dst string) error
+}
+
+// DigestVerifier is implemented by Fetchers that can verify a SHA256 digest
+// of the content they fetch before it's considered complete, instead of
+// leaving the caller to re-read the result afterward. HTTPFetcher is the
+// only one today, since it already streams through a hasher on the way to
+// its ".part" file.
+type DigestVerifier interface {
+	FetchVerified(ctx context.Context, rawURL, dst, expectedSHA256 string) error
+}
+
+var registry = map[string]Fetcher{}
+
+// Register associates a Fetcher with a URL scheme (e.g. "s3", "git").
+// Later calls with the same scheme replace the previous registration,
+// which is mainly useful for tests.
+func Register(scheme string, f Fetcher) {
+	registry[scheme] = f
+}
+
+func init() {
+	Register("http", &HTTPFetcher{})
+	Register("https", &HTTPFetcher{})
+	Register("git", &GitFetcher{})
+	Register("s3", &S3Fetcher{})
+	Register("file", &FileFetcher{})
+	Register("torrent", &TorrentFetcher{})
+	Register("magnet", &TorrentFetcher{})
+}
+
+// Fetch resolves rawURL to a scheme, looks up the registered Fetcher for it
+// and delegates to it. rawURL may carry a go-getter style forced scheme
+// prefix ("git::https://github.com/org/repo") to pick a Fetcher that
+// differs from the URL's own scheme.
+func Fetch(ctx context.Context, rawURL, dst string) error {
+	f, rest, err := resolve(rawURL)
+	if err != nil {
+		return err
+	}
+	return f.Fetch(ctx, rest, dst)
+}
+
+// FetchVerified behaves like Fetch, but when expectedSHA256 is non-empty and
+// the resolved Fetcher implements DigestVerifier, it verifies the digest as
+// part of the fetch itself (e.g. before renaming a temp file into place)
+// instead of requiring the caller to re-read dst afterward. Fetchers that
+// don't implement DigestVerifier ignore expectedSHA256 entirely; use
+// SupportsVerification to find out which case applies.
+func FetchVerified(ctx context.Context, rawURL, dst, expectedSHA256 string) error {
+	f, rest, err := resolve(rawURL)
+	if err != nil {
+		return err
+	}
+	if expectedSHA256 != "" {
+		if dv, ok := f.(DigestVerifier); ok {
+			return dv.FetchVerified(ctx, rest, dst, expectedSHA256)
+		}
+	}
+	return f.Fetch(ctx, rest, dst)
+}
+
+// SupportsVerification reports whether the Fetcher registered for rawURL's
+// scheme implements DigestVerifier, i.e. whether FetchVerified will verify
+// expectedSHA256 itself rather than leaving it to the caller.
+func SupportsVerification(rawURL string) bool {
+	f, _, err := resolve(rawURL)
+	if err != nil {
+		return false
+	}
+	_, ok := f.(DigestVerifier)
+	return ok
+}
+
+// resolve looks up the Fetcher registered for rawURL's scheme, stripping
+// any go-getter style forced scheme prefix first.
+func resolve(rawURL string) (f Fetcher, rest string, err error) {
+	scheme, rest := splitForcedScheme(rawURL)
+	if scheme == "" {
+		scheme = urlScheme(rawURL)
+	}
+
+	f, ok := registry[scheme]
+	if !ok {
+		return nil, "", fmt.Errorf("fetch: no fetcher registered for scheme %q (url: %s)", scheme, rawURL)
+	}
+	return f, rest, nil
+}
+
+// splitForcedScheme splits a go-getter style "scheme::rest" prefix off
+// rawURL. It returns ("", rawURL) when there's no such prefix.
+func splitForcedScheme(rawURL string) (scheme, rest string) {
+	idx := strings.Index(rawURL, "::")
+	if idx < 0 {
+		return "", rawURL
+	}
+	return rawURL[:idx], rawURL[idx+2:]
+}
+
+// urlScheme extracts the "scheme://" part of a URL without pulling in the
+// full net/url parsing machinery, since that's all the dispatcher needs.
+// Magnet links are a special case: they're "magnet:?xt=..." with a single
+// colon and no "//", so they'd never match the "://" scan otherwise.
+func urlScheme(rawURL string) string {
+	if strings.HasPrefix(rawURL, "magnet:") {
+		return "magnet"
+	}
+	idx := strings.Index(rawURL, "://")
+	if idx < 0 {
+		return ""
+	}
+	return rawURL[:idx]
+}
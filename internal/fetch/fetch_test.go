@@ -0,0 +1,126 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHTTPFetcherDownloadsToDestination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("password\nadmin\n"))
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "wordlist.txt")
+	f := &HTTPFetcher{}
+	if err := f.Fetch(context.Background(), srv.URL, dst); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("reading downloaded file: %v", err)
+	}
+	if string(got) != "password\nadmin\n" {
+		t.Fatalf("unexpected contents: %q", got)
+	}
+}
+
+func TestHTTPFetcherReportsProgress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+
+	var updates []int64
+	f := &HTTPFetcher{Progress: progressFunc(func(downloaded, total int64) {
+		updates = append(updates, downloaded)
+	})}
+
+	dst := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := f.Fetch(context.Background(), srv.URL, dst); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if len(updates) == 0 {
+		t.Fatal("expected at least one progress update")
+	}
+	if last := updates[len(updates)-1]; last != 10 {
+		t.Fatalf("expected final progress update to report 10 bytes, got %d", last)
+	}
+}
+
+func TestHTTPFetcherFetchVerifiedAcceptsMatchingDigest(t *testing.T) {
+	const body = "password\nadmin\n"
+	sum := sha256.Sum256([]byte(body))
+	digest := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "wordlist.txt")
+	f := &HTTPFetcher{}
+	if err := f.FetchVerified(context.Background(), srv.URL, dst, digest); err != nil {
+		t.Fatalf("FetchVerified: %v", err)
+	}
+	if _, err := os.Stat(dst + ".part"); !os.IsNotExist(err) {
+		t.Fatalf("expected .part file to be gone after a verified download, got err=%v", err)
+	}
+}
+
+func TestHTTPFetcherFetchVerifiedRejectsMismatchAndKeepsPartFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("password\nadmin\n"))
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "wordlist.txt")
+	f := &HTTPFetcher{}
+	err := f.FetchVerified(context.Background(), srv.URL, dst, "0000000000000000000000000000000000000000000000000000000000000000")
+	if err == nil {
+		t.Fatal("expected a digest mismatch error")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("expected dst to not exist after a failed verification, got err=%v", err)
+	}
+	if _, err := os.Stat(dst + ".part"); err != nil {
+		t.Fatalf("expected the .part file to be kept around for a later resume, got err=%v", err)
+	}
+}
+
+// progressFunc adapts a plain func to the Progress interface for tests.
+type progressFunc func(downloaded, total int64)
+
+func (f progressFunc) Update(downloaded, total int64) { f(downloaded, total) }
+
+func TestCopyPathCopiesDirectories(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("writing source file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatalf("making subdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatalf("writing nested source file: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "out")
+	if err := copyPath(src, dst); err != nil {
+		t.Fatalf("copyPath: %v", err)
+	}
+
+	for _, rel := range []string{"a.txt", filepath.Join("sub", "b.txt")} {
+		if _, err := os.Stat(filepath.Join(dst, rel)); err != nil {
+			t.Fatalf("expected %s to be copied: %v", rel, err)
+		}
+	}
+}
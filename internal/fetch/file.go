@@ -0,0 +1,14 @@
+package fetch
+
+import (
+	"context"
+	"strings"
+)
+
+// FileFetcher fetches "file://" sources, i.e. it just copies a path that's
+// already local.
+type FileFetcher struct{}
+
+func (f *FileFetcher) Fetch(ctx context.Context, rawURL, dst string) error {
+	return copyPath(strings.TrimPrefix(rawURL, "file://"), dst)
+}
@@ -0,0 +1,87 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// GitFetcher fetches "git::<repo-url>[//subpath][?ref=<ref>]" sources with
+// a shallow clone, optionally checking out a specific ref and/or pulling
+// just one subdirectory out of the checkout.
+type GitFetcher struct{}
+
+func (f *GitFetcher) Fetch(ctx context.Context, rawURL, dst string) error {
+	repoURL, subpath, ref := parseGitURL(rawURL)
+
+	tmpDir, err := os.MkdirTemp("", "wordlistctl-git-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cloneOpts := &git.CloneOptions{URL: repoURL, Depth: 1}
+	if ref != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(ref)
+	}
+
+	repo, err := git.PlainCloneContext(ctx, tmpDir, false, cloneOpts)
+	if err != nil && ref != "" {
+		// ref might be a tag rather than a branch; clone the default branch
+		// and let the ResolveRevision/Checkout below find it instead.
+		cloneOpts.ReferenceName = ""
+		repo, err = git.PlainCloneContext(ctx, tmpDir, false, cloneOpts)
+	}
+	if err != nil {
+		return fmt.Errorf("git: cloning %s: %w", repoURL, err)
+	}
+
+	if ref != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return fmt.Errorf("git: resolving ref %q: %w", ref, err)
+		}
+		wt, err := repo.Worktree()
+		if err != nil {
+			return err
+		}
+		if err := wt.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+			return fmt.Errorf("git: checking out %q: %w", ref, err)
+		}
+	}
+
+	src := tmpDir
+	if subpath != "" {
+		src = filepath.Join(tmpDir, subpath)
+	}
+	return copyPath(src, dst)
+}
+
+// parseGitURL splits a "git::" source into the repo URL git actually
+// understands, an optional subpath (the go-getter "//subdir" convention)
+// and an optional "?ref=" query parameter.
+func parseGitURL(rawURL string) (repoURL, subpath, ref string) {
+	u := rawURL
+	if idx := strings.Index(u, "?"); idx >= 0 {
+		if values, err := url.ParseQuery(u[idx+1:]); err == nil {
+			ref = values.Get("ref")
+		}
+		u = u[:idx]
+	}
+
+	if schemeEnd := strings.Index(u, "://"); schemeEnd >= 0 {
+		if sep := strings.Index(u[schemeEnd+3:], "//"); sep >= 0 {
+			abs := schemeEnd + 3 + sep
+			subpath = u[abs+2:]
+			u = u[:abs]
+		}
+	}
+
+	return u, subpath, ref
+}
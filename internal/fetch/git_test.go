@@ -0,0 +1,92 @@
+package fetch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestGitFetcherClonesLocalRepo(t *testing.T) {
+	repoDir := initLocalRepo(t, map[string]string{"wordlist.txt": "admin\npassword\n"})
+	dst := filepath.Join(t.TempDir(), "out")
+
+	f := &GitFetcher{}
+	if err := f.Fetch(context.Background(), "file://"+repoDir, dst); err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	contents, err := os.ReadFile(filepath.Join(dst, "wordlist.txt"))
+	if err != nil {
+		t.Fatalf("couldn't read cloned file: %v", err)
+	}
+	if string(contents) != "admin\npassword\n" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+}
+
+func TestGitFetcherHonorsSubpath(t *testing.T) {
+	repoDir := initLocalRepo(t, map[string]string{
+		"lists/wordlist.txt": "admin\npassword\n",
+		"README.md":          "not a wordlist",
+	})
+	dst := filepath.Join(t.TempDir(), "out")
+
+	f := &GitFetcher{}
+	if err := f.Fetch(context.Background(), "file://"+repoDir+"//lists", dst); err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	contents, err := os.ReadFile(filepath.Join(dst, "wordlist.txt"))
+	if err != nil {
+		t.Fatalf("couldn't read cloned file: %v", err)
+	}
+	if string(contents) != "admin\npassword\n" {
+		t.Fatalf("unexpected contents: %q", contents)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "README.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected only the //lists subpath to be copied, got err=%v", err)
+	}
+}
+
+// initLocalRepo creates a throwaway git repo with a single commit containing
+// files, returning its filesystem path so it can be cloned over "file://".
+func initLocalRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("initializing repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("getting worktree: %v", err)
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("creating dir for %q: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("writing %q: %v", name, err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatalf("staging %q: %v", name, err)
+		}
+	}
+
+	_, err = wt.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)},
+	})
+	if err != nil {
+		t.Fatalf("committing: %v", err)
+	}
+
+	return dir
+}
@@ -0,0 +1,174 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+)
+
+// userAgent identifies wordlistctl to servers that refuse requests with no
+// (or a suspicious) User-Agent.
+const userAgent = "wordlistctl/1.0 (+https://github.com/casalinovalerio/wordlistctl)"
+
+// Progress lets callers observe how an HTTPFetcher download is going,
+// without HTTPFetcher having to know anything about progress bars.
+type Progress interface {
+	Update(downloaded, total int64)
+}
+
+// consoleProgress is the default Progress: a single line updated in place.
+type consoleProgress struct{}
+
+func (consoleProgress) Update(downloaded, total int64) {
+	if total > 0 {
+		fmt.Printf("\r==> %d/%d bytes (%.0f%%)", downloaded, total, 100*float64(downloaded)/float64(total))
+	} else {
+		fmt.Printf("\r==> %d bytes", downloaded)
+	}
+}
+
+// progressWriter turns Progress into an io.Writer we can feed to io.MultiWriter.
+type progressWriter struct {
+	progress   Progress
+	downloaded int64
+	total      int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.downloaded += int64(len(b))
+	p.progress.Update(p.downloaded, p.total)
+	return len(b), nil
+}
+
+// HTTPFetcher fetches plain http(s):// URLs. It resumes a previously
+// interrupted download (via a "dst+.part" file and a Range request),
+// follows redirects, and streams the body straight to disk.
+type HTTPFetcher struct {
+	// Client lets callers plug in a custom *http.Client (timeouts, proxies,
+	// tests...); the zero value falls back to http.DefaultClient.
+	Client *http.Client
+
+	// Progress reports download progress as bytes arrive; the zero value
+	// falls back to printing a single line updated in place.
+	Progress Progress
+}
+
+func (f *HTTPFetcher) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
+	}
+	return http.DefaultClient
+}
+
+func (f *HTTPFetcher) progress() Progress {
+	if f.Progress != nil {
+		return f.Progress
+	}
+	return consoleProgress{}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL, dst string) error {
+	return f.fetch(ctx, rawURL, dst, "")
+}
+
+// FetchVerified behaves like Fetch, but hashes the body as it streams to
+// disk and checks it against expectedSHA256 before renaming the ".part"
+// file into place, instead of requiring a second full read of dst
+// afterward. On a mismatch, the ".part" file is left on disk exactly as a
+// plain failed download would be, so a later resume attempt still has
+// something to resume from.
+func (f *HTTPFetcher) FetchVerified(ctx context.Context, rawURL, dst, expectedSHA256 string) error {
+	return f.fetch(ctx, rawURL, dst, expectedSHA256)
+}
+
+func (f *HTTPFetcher) fetch(ctx context.Context, rawURL, dst, expectedSHA256 string) error {
+	partPath := dst + ".part"
+
+	var resumeFrom int64
+	if fi, err := os.Stat(partPath); err == nil {
+		resumeFrom = fi.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("fetch: unexpected status fetching %s: %s", rawURL, resp.Status)
+	}
+
+	resuming := resumeFrom > 0 && resp.StatusCode == http.StatusPartialContent
+	openFlags := os.O_CREATE | os.O_WRONLY
+	if resuming {
+		openFlags |= os.O_APPEND
+	} else {
+		openFlags |= os.O_TRUNC
+		resumeFrom = 0
+	}
+
+	out, err := os.OpenFile(partPath, openFlags, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var hasher hash.Hash
+	if expectedSHA256 != "" {
+		hasher = sha256.New()
+		if resuming {
+			// Seed the hasher with the bytes already on disk, since we
+			// verify the digest of the whole file, not just this request.
+			existing, err := os.Open(partPath)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(hasher, io.LimitReader(existing, resumeFrom))
+			existing.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	total := resp.ContentLength
+	if total >= 0 {
+		total += resumeFrom
+	}
+	progress := &progressWriter{progress: f.progress(), downloaded: resumeFrom, total: total}
+
+	var writer io.Writer = io.MultiWriter(out, progress)
+	if hasher != nil {
+		writer = io.MultiWriter(out, hasher, progress)
+	}
+
+	if _, err := io.Copy(writer, resp.Body); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	if hasher != nil {
+		if got := hex.EncodeToString(hasher.Sum(nil)); got != expectedSHA256 {
+			return fmt.Errorf("fetch: sha256 mismatch for %s: expected %s, got %s", rawURL, expectedSHA256, got)
+		}
+	}
+
+	out.Close()
+
+	return os.Rename(partPath, dst)
+}
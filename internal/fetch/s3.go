@@ -0,0 +1,57 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Fetcher fetches "s3://bucket/key" sources, using whatever AWS
+// credentials the environment already provides (env vars, shared config,
+// instance role, ...).
+type S3Fetcher struct{}
+
+func (f *S3Fetcher) Fetch(ctx context.Context, rawURL, dst string) error {
+	bucket, key, err := parseS3URL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("s3: loading AWS config: %w", err)
+	}
+
+	out, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("s3: getting s3://%s/%s: %w", bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, out.Body)
+	return err
+}
+
+func parseS3URL(rawURL string) (bucket, key string, err error) {
+	trimmed := strings.TrimPrefix(rawURL, "s3://")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("s3: invalid url %q, expected s3://bucket/key", rawURL)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,122 @@
+/*
+Package search provides an in-memory inverted index over wordlist metadata,
+plus a small query DSL:
+
+	password tag:leak size:>100MB updated:>2023-01-01 name:~rock
+
+Bare words are matched as tokens against the name/description/tags of each
+document; "tag:", "size:" and "updated:" narrow the result set; "name:~x"
+fuzzy-matches the name against x (Levenshtein distance <= 2). Results are
+ranked by how many of the bare-word tokens they matched.
+*/
+package search
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Document is everything the index needs to know about one wordlist. It
+// deliberately doesn't depend on wordlistctl's own Wordlist/WordlistInfo
+// types, so the index can be built, tested and reused independently of how
+// archive.json happens to be shaped.
+type Document struct {
+	ID          int
+	Name        string
+	Description string
+	Tags        []string
+	SizeBytes   int64
+	Updated     time.Time
+}
+
+// Index is an in-memory inverted index (token -> document IDs) over a set
+// of Documents.
+type Index struct {
+	docs   []Document
+	tokens map[string][]int
+}
+
+// Build tokenizes every document's name, description and tags (lowercase
+// folded, splitting on anything that isn't a unicode letter or digit) and
+// indexes them by token.
+func Build(docs []Document) *Index {
+	idx := &Index{docs: docs, tokens: make(map[string][]int)}
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, token := range tokenize(doc.Name, doc.Description, strings.Join(doc.Tags, " ")) {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			idx.tokens[token] = append(idx.tokens[token], doc.ID)
+		}
+	}
+	return idx
+}
+
+func tokenize(fields ...string) []string {
+	var tokens []string
+	for _, field := range fields {
+		tokens = append(tokens, strings.FieldsFunc(strings.ToLower(field), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return tokens
+}
+
+// Result is one match, ranked by Score (the number of query tokens it matched).
+type Result struct {
+	Document Document
+	Score    int
+}
+
+// Search runs q against the index and returns matches ordered by Score,
+// highest first.
+func (idx *Index) Search(q Query) []Result {
+	scores := make(map[int]int)
+
+	if len(q.Terms) == 0 {
+		for _, doc := range idx.docs {
+			scores[doc.ID] = 0
+		}
+	} else {
+		for _, term := range q.Terms {
+			for _, id := range idx.tokens[term] {
+				scores[id]++
+			}
+		}
+		// Bare words must all be present somewhere in the document for it
+		// to count as a match at all.
+		for id, score := range scores {
+			if score < 1 {
+				delete(scores, id)
+			}
+		}
+	}
+
+	byID := make(map[int]Document, len(idx.docs))
+	for _, doc := range idx.docs {
+		byID[doc.ID] = doc
+	}
+
+	var results []Result
+	for id, score := range scores {
+		doc, ok := byID[id]
+		if !ok || !q.matches(doc) {
+			continue
+		}
+		results = append(results, Result{Document: doc, Score: score})
+	}
+
+	sortByScoreDesc(results)
+	return results
+}
+
+func sortByScoreDesc(results []Result) {
+	for i := 1; i < len(results); i++ {
+		for j := i; j > 0 && results[j].Score > results[j-1].Score; j-- {
+			results[j], results[j-1] = results[j-1], results[j]
+		}
+	}
+}
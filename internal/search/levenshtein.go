@@ -0,0 +1,75 @@
+package search
+
+// Levenshtein returns the edit distance between a and b.
+func Levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 {
+		return len(br)
+	}
+	if len(br) == 0 {
+		return len(ar)
+	}
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(
+				curr[j-1]+1,
+				prev[j]+1,
+				prev[j-1]+cost,
+			)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
+
+// fuzzySubstringMatch reports whether some substring of s is within
+// maxDist of query, rather than requiring the whole of s to be close to
+// query. This is what lets a short fuzzy query match a word embedded in a
+// longer one (e.g. "rock" against "rockyou").
+func fuzzySubstringMatch(s, query string, maxDist int) bool {
+	runes := []rune(s)
+	qlen := len([]rune(query))
+
+	if len(runes) <= qlen+maxDist {
+		return Levenshtein(s, query) <= maxDist
+	}
+
+	minLen := qlen - maxDist
+	if minLen < 1 {
+		minLen = 1
+	}
+	maxLen := qlen + maxDist
+
+	for l := minLen; l <= maxLen; l++ {
+		for start := 0; start+l <= len(runes); start++ {
+			if Levenshtein(string(runes[start:start+l]), query) <= maxDist {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
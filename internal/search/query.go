@@ -0,0 +1,154 @@
+package search
+
+import (
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// sizePredicate is one "size:<op><value>" term, e.g. size:>100MB.
+type sizePredicate struct {
+	op    string
+	bytes int64
+}
+
+func (p sizePredicate) matches(n int64) bool {
+	return compare(n, p.op, p.bytes)
+}
+
+// datePredicate is one "updated:<op><date>" term, e.g. updated:>2023-01-01.
+type datePredicate struct {
+	op   string
+	when time.Time
+}
+
+func (p datePredicate) matches(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	return compare(t.Unix(), p.op, p.when.Unix())
+}
+
+func compare(got int64, op string, want int64) bool {
+	switch op {
+	case ">":
+		return got > want
+	case ">=":
+		return got >= want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	default: // "="
+		return got == want
+	}
+}
+
+// Query is a parsed search DSL expression: bare words to match in the
+// inverted index, plus tag/size/updated filters and an optional fuzzy name
+// match.
+type Query struct {
+	Terms     []string
+	Tags      []string
+	SizePreds []sizePredicate
+	DatePreds []datePredicate
+	FuzzyName string
+}
+
+// ParseQuery turns a raw query string such as
+//
+//	password tag:leak size:>100MB updated:>2023-01-01 name:~rock
+//
+// into a Query. Unrecognized "field:value" prefixes are treated as bare
+// words, same as a word with no colon at all.
+func ParseQuery(raw string) Query {
+	var q Query
+	for _, token := range strings.Fields(raw) {
+		switch {
+		case strings.HasPrefix(token, "tag:"):
+			q.Tags = append(q.Tags, strings.ToLower(strings.TrimPrefix(token, "tag:")))
+
+		case strings.HasPrefix(token, "name:~"):
+			q.FuzzyName = strings.ToLower(strings.TrimPrefix(token, "name:~"))
+
+		case strings.HasPrefix(token, "size:"):
+			op, rest := splitOperator(strings.TrimPrefix(token, "size:"))
+			if bytes, err := ParseSize(rest); err == nil {
+				q.SizePreds = append(q.SizePreds, sizePredicate{op: op, bytes: bytes})
+			}
+
+		case strings.HasPrefix(token, "updated:"):
+			op, rest := splitOperator(strings.TrimPrefix(token, "updated:"))
+			if when, err := time.Parse(dateLayout, rest); err == nil {
+				q.DatePreds = append(q.DatePreds, datePredicate{op: op, when: when})
+			}
+
+		default:
+			q.Terms = append(q.Terms, strings.ToLower(token))
+		}
+	}
+	return q
+}
+
+func splitOperator(s string) (op, rest string) {
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(s, candidate) {
+			return candidate, s[len(candidate):]
+		}
+	}
+	return "=", s
+}
+
+// matches reports whether doc satisfies every filter in q (tags, size,
+// updated, fuzzy name). It does not consider q.Terms -- those are scored by
+// Index.Search instead of gating a result in or out.
+func (q Query) matches(doc Document) bool {
+	if !hasAllTags(doc.Tags, q.Tags) {
+		return false
+	}
+	for _, p := range q.SizePreds {
+		if !p.matches(doc.SizeBytes) {
+			return false
+		}
+	}
+	for _, p := range q.DatePreds {
+		if !p.matches(doc.Updated) {
+			return false
+		}
+	}
+	if q.FuzzyName != "" && !fuzzyNameMatches(doc.Name, q.FuzzyName) {
+		return false
+	}
+	return true
+}
+
+// fuzzyNameMatches reports whether any word in name is within Levenshtein
+// distance 2 of query, checking substrings of each word rather than the
+// word as a whole -- otherwise a short query like "rock" could never match
+// a longer name like "rockyou" (edit distance 3) let alone "rockyou.txt".
+func fuzzyNameMatches(name, query string) bool {
+	const maxDist = 2
+	for _, word := range tokenize(name) {
+		if fuzzySubstringMatch(word, query, maxDist) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAllTags(have, want []string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	set := make(map[string]bool, len(have))
+	for _, t := range have {
+		set[strings.ToLower(t)] = true
+	}
+	for _, w := range want {
+		if !set[w] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	when, err := time.Parse(dateLayout, s)
+	if err != nil {
+		t.Fatalf("parsing date %q: %v", s, err)
+	}
+	return when
+}
+
+func TestFuzzyNameMatchesSubstring(t *testing.T) {
+	docs := []Document{
+		{ID: 1, Name: "rockyou.txt"},
+		{ID: 2, Name: "common-passwords.txt"},
+	}
+	idx := Build(docs)
+
+	results := idx.Search(ParseQuery("name:~rock"))
+	if len(results) != 1 || results[0].Document.ID != 1 {
+		t.Fatalf("expected only rockyou.txt to match name:~rock, got %+v", results)
+	}
+}
+
+func TestTagAndSizeAndUpdatedPredicates(t *testing.T) {
+	docs := []Document{
+		{ID: 1, Name: "big-leak", Tags: []string{"leak", "password"}, SizeBytes: 200 << 20, Updated: mustDate(t, "2023-06-01")},
+		{ID: 2, Name: "small-leak", Tags: []string{"leak"}, SizeBytes: 10 << 20, Updated: mustDate(t, "2020-01-01")},
+		{ID: 3, Name: "untagged", SizeBytes: 300 << 20, Updated: mustDate(t, "2024-01-01")},
+	}
+	idx := Build(docs)
+
+	results := idx.Search(ParseQuery("tag:leak size:>100MB updated:>2023-01-01"))
+	if len(results) != 1 || results[0].Document.ID != 1 {
+		t.Fatalf("expected only doc 1 to match, got %+v", results)
+	}
+}
+
+func TestSearchRanksByMatchedTokenCount(t *testing.T) {
+	docs := []Document{
+		{ID: 1, Name: "admin-password-list", Description: "common passwords"},
+		{ID: 2, Name: "password-list"},
+	}
+	idx := Build(docs)
+
+	results := idx.Search(ParseQuery("admin password"))
+	if len(results) != 2 {
+		t.Fatalf("expected both docs to match, got %+v", results)
+	}
+	if results[0].Document.ID != 1 || results[0].Score != 2 {
+		t.Fatalf("expected doc 1 to rank first with score 2, got %+v", results[0])
+	}
+	if results[1].Document.ID != 2 || results[1].Score != 1 {
+		t.Fatalf("expected doc 2 to rank second with score 1, got %+v", results[1])
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	var oneG float64 = 1 << 30
+	cases := map[string]int64{
+		"500":   500,
+		"1K":    1 << 10,
+		"1.2G":  int64(1.2 * oneG),
+		"100MB": 100 << 20,
+		"2TB":   2 << 40,
+	}
+	for input, want := range cases {
+		got, err := ParseSize(input)
+		if err != nil {
+			t.Fatalf("ParseSize(%q): %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseSize(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"rock", "rock", 0},
+		{"rock", "rocks", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, c := range cases {
+		if got := Levenshtein(c.a, c.b); got != c.want {
+			t.Fatalf("Levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = map[string]int64{
+	"B":  1,
+	"K":  1 << 10,
+	"KB": 1 << 10,
+	"M":  1 << 20,
+	"MB": 1 << 20,
+	"G":  1 << 30,
+	"GB": 1 << 30,
+	"T":  1 << 40,
+	"TB": 1 << 40,
+}
+
+// ParseSize turns a human-readable size such as "1.2G" or "500MB" into a
+// byte count. A bare number (no unit) is taken to already be bytes.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("search: empty size")
+	}
+
+	i := len(s)
+	for i > 0 && (s[i-1] < '0' || s[i-1] > '9') && s[i-1] != '.' {
+		i--
+	}
+	numPart, unitPart := s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+
+	value, err := strconv.ParseFloat(numPart, 64)
+	if err != nil {
+		return 0, fmt.Errorf("search: invalid size %q: %w", s, err)
+	}
+
+	if unitPart == "" {
+		return int64(value), nil
+	}
+	unit, ok := sizeUnits[unitPart]
+	if !ok {
+		return 0, fmt.Errorf("search: unknown size unit %q in %q", unitPart, s)
+	}
+	return int64(value * float64(unit)), nil
+}
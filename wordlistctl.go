@@ -20,35 +20,43 @@ as stated in the main document, from sepehrdad@blackarch.org
 package main
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"path"
-	"regexp"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"golang.org/x/sys/unix"
 
-	"github.com/h2non/filetype"
+	"github.com/casalinovalerio/wordlistctl/internal/archive"
+	fetcher "github.com/casalinovalerio/wordlistctl/internal/fetch"
+	textsearch "github.com/casalinovalerio/wordlistctl/internal/search"
 )
 
 // flag global variables to usage and cli parsing
 var (
-	DEFAULTSTR = "."
-	search     = flag.NewFlagSet("search", flag.ExitOnError)
-	fetch      = flag.NewFlagSet("fetch", flag.ExitOnError)
-	list       = flag.NewFlagSet("list", flag.ExitOnError)
-	listGroup  = list.String("g", DEFAULTSTR, "Specify a group to list: {usernames,passwords,discovery,fuzzing,misc}")
-	fetchGroup = fetch.String("g", DEFAULTSTR, "Specify a group to fetch: {usernames,passwords,discovery,fuzzing,misc}")
-	fetchBase  = fetch.String("b", "/usr/share/wordlists", "Base directory to store wordlists")
-	fetchName  = fetch.String("n", DEFAULTSTR, "The name of the desired wordlist to download")
+	DEFAULTSTR  = "."
+	search      = flag.NewFlagSet("search", flag.ExitOnError)
+	fetch       = flag.NewFlagSet("fetch", flag.ExitOnError)
+	list        = flag.NewFlagSet("list", flag.ExitOnError)
+	update      = flag.NewFlagSet("update", flag.ExitOnError)
+	listGroup   = list.String("g", DEFAULTSTR, "Specify a group to list: {usernames,passwords,discovery,fuzzing,misc}")
+	fetchGroup  = fetch.String("g", DEFAULTSTR, "Specify a group to fetch: {usernames,passwords,discovery,fuzzing,misc}")
+	fetchBase   = fetch.String("b", "/usr/share/wordlists", "Base directory to store wordlists")
+	fetchName   = fetch.String("n", DEFAULTSTR, "The name of the desired wordlist to download")
+	fetchJobs   = fetch.Int("j", runtime.NumCPU(), "Number of concurrent downloads when fetching a whole group")
+	updateForce = update.Bool("f", false, "Force a refresh of archive.json even if the server reports it's unchanged")
 )
 
 // Default locations of archive.json, which contains the data needed to this program to run
@@ -60,16 +68,26 @@ var (
 // WordlistInfo is made to wrap the JSON info in archive.json
 // which is made like so {"name":"...","info":{"url":"...","group":"..."...}
 type WordlistInfo struct {
-	URL     string `json:"url,omitempty"`
-	Group   string `json:"group,omitempty"`
-	Size    string `json:"size,omitempty"`
-	Updated string `json:"updated,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Group       string   `json:"group,omitempty"`
+	Size        string   `json:"size,omitempty"`
+	Updated     string   `json:"updated,omitempty"`
+	SHA256      string   `json:"sha256,omitempty"`
+	SizeBytes   int64    `json:"size_bytes,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
 }
 
 // Wordlist is container for 1 wordlist and its info
 type Wordlist struct {
 	Name string       `json:"name,omitempty"`
 	Info WordlistInfo `json:"info,omitempty"`
+
+	// parsedSize and parsedUpdated cache Info.Size ("1.2G") and
+	// Info.Updated ("2023-01-01") in a form search predicates can compare
+	// against in O(1); they're filled in once by getAllWordlists.
+	parsedSize    int64
+	parsedUpdated time.Time
 }
 
 // Just a wrapper for error messages
@@ -78,12 +96,12 @@ func report(msg string) {
 }
 
 func searchUsage() {
-	fmt.Println("==> [SEARCH USAGE]: wordlistctl search 'search-term'")
+	fmt.Println("==> [SEARCH USAGE]: wordlistctl search 'term tag:leak size:>100MB updated:>2023-01-01 name:~rock'")
 	search.PrintDefaults()
 }
 
 func fetchUsage() {
-	fmt.Println("==> [FETCH USAGE]: wordlistctl fetch -[bgn] [ARGS]")
+	fmt.Println("==> [FETCH USAGE]: wordlistctl fetch -[bgnj] [ARGS]")
 	fetch.PrintDefaults()
 }
 
@@ -92,13 +110,20 @@ func listUsage() {
 	list.PrintDefaults()
 }
 
+func updateUsage() {
+	fmt.Println("==> [UPDATE USAGE]: wordlistctl update -[f] [ARGS]")
+	update.PrintDefaults()
+}
+
 func usage() {
-	fmt.Printf("[USAGE]: wordlistctl {search,list,fetch} -[hgb] [ARGS]\n\n")
+	fmt.Printf("[USAGE]: wordlistctl {search,list,fetch,update} -[hgb] [ARGS]\n\n")
 	searchUsage()
 	fmt.Printf("\n")
 	listUsage()
 	fmt.Printf("\n")
 	fetchUsage()
+	fmt.Printf("\n")
+	updateUsage()
 	os.Exit(1)
 }
 
@@ -108,13 +133,16 @@ func main() {
 	search.Usage = searchUsage
 	fetch.Usage = fetchUsage
 	list.Usage = listUsage
+	update.Usage = updateUsage
 	flag.Parse()
 
-	// If file doesn't exist just re-download it
+	// If archive.json doesn't exist yet, fetch it instead of bailing out.
 	if !fileExist(repoLocation) {
-		report("Cannot find archive.json (fatal)")
-		fmt.Println("Run: \nwget -O", repoLocation, repoURL, "\nTo re-download archive.json")
-		os.Exit(2)
+		fmt.Println("==> archive.json not found, running update...")
+		if err := runUpdate(true); err != nil {
+			report("Couldn't fetch archive.json: " + err.Error())
+			os.Exit(2)
+		}
 	}
 
 	if flag.NArg() < 1 {
@@ -123,11 +151,20 @@ func main() {
 	}
 
 	// Making this check before we load the wordlist archive into memory
-	if os.Args[1] != "search" && os.Args[1] != "list" && os.Args[1] != "fetch" {
+	if os.Args[1] != "search" && os.Args[1] != "list" && os.Args[1] != "fetch" && os.Args[1] != "update" {
 		report("Please input a valid mode")
 		usage()
 	}
 
+	if os.Args[1] == "update" {
+		update.Parse(os.Args[2:])
+		if err := runUpdate(*updateForce); err != nil {
+			report("Couldn't update archive.json: " + err.Error())
+			os.Exit(2)
+		}
+		return
+	}
+
 	// Preloading the wordlists
 	wordlistArray := getAllWordlists(repoLocation)
 
@@ -150,7 +187,7 @@ func main() {
 				report("You should choose either a group or a name...")
 				os.Exit(2)
 			}
-			fetchMulti(wordlistArray, *fetchGroup, *fetchBase)
+			fetchMulti(wordlistArray, *fetchGroup, *fetchBase, *fetchJobs)
 		} else {
 			if *fetchGroup != DEFAULTSTR {
 				report("You shouldn't choose bot a group and a name...")
@@ -190,6 +227,16 @@ func getAllWordlists(repoName string) []Wordlist {
 	if err != nil {
 		panic(err)
 	}
+
+	for i := range wordlists {
+		if bytes, err := textsearch.ParseSize(wordlists[i].Info.Size); err == nil {
+			wordlists[i].parsedSize = bytes
+		}
+		if when, err := time.Parse("2006-01-02", wordlists[i].Info.Updated); err == nil {
+			wordlists[i].parsedUpdated = when
+		}
+	}
+
 	return wordlists
 }
 
@@ -203,110 +250,113 @@ func convertWordlistToMap(arrayed []Wordlist) map[string]WordlistInfo {
 	return mapped
 }
 
-// DownloadFile will download a url and store it in local filepath.
-// It writes to the destination file as it downloads it, without
-// loading the entire file into memory.
-// https://progolang.com/how-to-download-files-in-go/
-func downloadFile(url string, filepath string) error {
-	// Create the file
-	out, err := os.Create(filepath)
+// repoMeta is persisted alongside archive.json (as repoLocation+".meta.json")
+// so that subsequent updates can ask the server for only what changed.
+type repoMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func metaLocation() string {
+	return repoLocation + ".meta.json"
+}
+
+func readRepoMeta() repoMeta {
+	var meta repoMeta
+	data, err := ioutil.ReadFile(metaLocation())
 	if err != nil {
-		return err
+		return meta
 	}
-	defer out.Close()
+	json.Unmarshal(data, &meta)
+	return meta
+}
 
-	// Get the data
-	resp, err := http.Get(url)
+func writeRepoMeta(meta repoMeta) error {
+	data, err := json.Marshal(meta)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	return ioutil.WriteFile(metaLocation(), data, 0644)
+}
 
-	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
+// runUpdate fetches repoURL into repoLocation, sending the ETag/Last-Modified
+// it remembers from the previous run so the server can reply 304 Not
+// Modified when nothing changed. Passing force skips that conditional
+// request entirely and always re-downloads.
+func runUpdate(force bool) error {
+	req, err := http.NewRequest(http.MethodGet, repoURL, nil)
 	if err != nil {
 		return err
 	}
-	return nil
-}
 
-// Decompress gzip archive
-func decompressGzip(targetdir string, archive string) string {
-	reader, err := os.Open(archive)
-	if err != nil {
-		fmt.Println("error")
+	if !force {
+		meta := readRepoMeta()
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
 	}
-	defer reader.Close()
 
-	gzReader, err := gzip.NewReader(reader)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return ""
+		return err
 	}
-	defer gzReader.Close()
+	defer resp.Body.Close()
 
-	target, err := os.Create(path.Join(targetdir, gzReader.Name))
-	if err != nil {
-		return ""
+	if resp.StatusCode == http.StatusNotModified {
+		fmt.Println("archive.json is already up to date")
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status fetching %s: %s", repoURL, resp.Status)
 	}
 
-	if _, err := io.Copy(target, gzReader); err != nil {
-		return ""
+	if err := os.MkdirAll(path.Dir(repoLocation), os.ModePerm); err != nil {
+		return err
 	}
 
-	if os.Remove(archive) != nil {
-		report("It was impossible to clean")
+	out, err := os.Create(repoLocation)
+	if err != nil {
+		return err
 	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return err
+	}
+	out.Close()
 
-	return target.Name()
+	meta := repoMeta{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if err := writeRepoMeta(meta); err != nil {
+		report("Couldn't persist update cache metadata: " + err.Error())
+	}
+
+	fmt.Println("archive.json updated")
+	return nil
 }
 
-func decompressTar(targetdir string, archive string) string {
-	reader, err := os.Open(archive)
+// verifySHA256 hashes filePath and compares it against expected, which is
+// hex-encoded (the same form archive.json's "sha256" field uses).
+func verifySHA256(filePath string, expected string) error {
+	f, err := os.Open(filePath)
 	if err != nil {
-		fmt.Println("error")
-	}
-	defer reader.Close()
-
-	// Decompress from tarball to final
-	tarReader := tar.NewReader(reader)
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return ""
-		}
-		target := path.Join(targetdir, header.Name)
+		return err
+	}
+	defer f.Close()
 
-		switch header.Typeflag {
-		case tar.TypeDir:
-			err = os.MkdirAll(target, os.FileMode(header.Mode))
-			if err != nil {
-				return ""
-			}
-			os.Chmod(target, os.FileMode(header.Mode))
-			os.Chtimes(target, header.AccessTime, header.ModTime)
-			break
-		case tar.TypeReg:
-			w, err := os.Create(target)
-			if err != nil {
-				return ""
-			}
-			_, err = io.Copy(w, tarReader)
-			if err != nil {
-				return ""
-			}
-			w.Close()
-			os.Chmod(target, os.FileMode(header.Mode))
-			os.Chtimes(target, header.AccessTime, header.ModTime)
-			return target
-
-		default:
-			log.Printf("unsupported type: %v", header.Typeflag)
-			break
-		}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return err
 	}
-	return ""
+
+	if got := hex.EncodeToString(hasher.Sum(nil)); got != expected {
+		return fmt.Errorf("sha256 mismatch for %s: expected %s, got %s", filePath, expected, got)
+	}
+	return nil
 }
 
 // To move files no matter of the partitions
@@ -334,42 +384,88 @@ func moveFile(sourcePath, destPath string) error {
 	return nil
 }
 
-func downloadAndExtract(url string, downloadPath string, finalPath string) {
-	fmt.Println("==> Downloading: \n", url)
-	downloadFile(url, downloadPath)
+// moveDir is moveFile's directory counterpart: it moves every file under
+// sourceDir into destDir (mirroring sourceDir's layout), no matter whether
+// the two live on the same partition, then removes whatever's left of
+// sourceDir.
+func moveDir(sourceDir, destDir string) error {
+	err := filepath.Walk(sourceDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		return moveFile(p, target)
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(sourceDir)
+}
+
+func downloadAndExtract(info WordlistInfo, downloadPath string, finalPath string) error {
+	fmt.Println("==> Downloading: \n", info.URL)
+	if err := fetcher.FetchVerified(context.Background(), info.URL, downloadPath, info.SHA256); err != nil {
+		return fmt.Errorf("fetching %s: %w", info.URL, err)
+	}
 	fmt.Println("Done!")
 
 	// Creating folder (group)
 	os.Mkdir(finalPath, os.ModePerm)
 
-	buf, _ := ioutil.ReadFile(downloadPath)
+	downloadInfo, err := os.Stat(downloadPath)
+	if err != nil {
+		return err
+	}
+	if downloadInfo.IsDir() {
+		// GitFetcher (a plain clone, with no "//subpath") and TorrentFetcher
+		// (a multi-file torrent) land a whole directory at downloadPath
+		// instead of a single archive; there's nothing to checksum or
+		// extract, so just move it into place as-is.
+		dest := path.Join(finalPath, path.Base(downloadPath))
+		if err := moveDir(downloadPath, dest); err != nil {
+			return err
+		}
+		fmt.Println("Wordlist saved to ", finalPath, "\nIt was smooth, wasn't it?")
+		return nil
+	}
 
-	if filetype.IsType(buf, filetype.Types["gz"]) {
-		fmt.Println("==> Extracting...")
-		intermediate := decompressGzip(os.TempDir(), downloadPath)
-		buf, _ := ioutil.ReadFile(intermediate)
-		if filetype.IsType(buf, filetype.Types["tar"]) {
-			final := decompressTar(finalPath, intermediate)
-			if os.Remove(intermediate) != nil {
-				report("It was impossible to clean")
-			}
-			if final != finalPath {
-				report("final and finalPath are not the same")
-			}
-		} else {
-			err := moveFile(intermediate, path.Join(finalPath, path.Base(intermediate)))
-			if err != nil {
-				panic(err)
-			}
+	// FetchVerified already checked the digest in-flight for fetchers that
+	// support it (HTTPFetcher); only fall back to a full re-read here for
+	// the ones that don't.
+	if info.SHA256 != "" && !fetcher.SupportsVerification(info.URL) {
+		if err := verifySHA256(downloadPath, info.SHA256); err != nil {
+			os.Remove(downloadPath)
+			return err
 		}
-	} else {
-		err := moveFile(downloadPath, path.Join(finalPath, path.Base(downloadPath)))
-		if err != nil {
-			panic(err)
+	}
+
+	fmt.Println("==> Extracting...")
+	written, err := archive.Extract(downloadPath, finalPath)
+	if err != nil {
+		report("Couldn't extract archive: " + err.Error())
+		// Fall back to keeping the raw download, it might just be a
+		// plain wordlist file with no recognizable container.
+		if err := moveFile(downloadPath, path.Join(finalPath, path.Base(downloadPath))); err != nil {
+			return err
 		}
+		fmt.Println("Wordlist saved to ", finalPath, "\nIt was smooth, wasn't it?")
+		return nil
 	}
 
+	if os.Remove(downloadPath) != nil {
+		report("It was impossible to clean")
+	}
+	_ = written
+
 	fmt.Println("Wordlist saved to ", finalPath, "\nIt was smooth, wasn't it?")
+	return nil
 }
 
 func printInfo(wordlist Wordlist) {
@@ -380,18 +476,34 @@ func printInfo(wordlist Wordlist) {
 
 	defer w.Flush()
 
-	fmt.Fprintf(w, ">"+wordlist.Name+"\t("+wordlist.Info.Size+")\t["+wordlist.Info.Updated+"]\n")
+	fmt.Fprintf(w, ">%s\t(%s)\t[%s]\n", wordlist.Name, wordlist.Info.Size, wordlist.Info.Updated)
 }
 
-func searchRoutine(term string, wordlists []Wordlist) {
-	for _, wordlist := range wordlists {
-		matched, err := regexp.MatchString(term, wordlist.Name)
-		if err != nil {
-			report("Error in regexpr... Not sure what it means")
-		}
-		if matched {
-			printInfo(wordlist)
+// buildSearchIndex turns wordlists into the textsearch package's document
+// form, keyed by their position in the slice so results can be mapped back.
+func buildSearchIndex(wordlists []Wordlist) (*textsearch.Index, map[int]Wordlist) {
+	docs := make([]textsearch.Document, len(wordlists))
+	byID := make(map[int]Wordlist, len(wordlists))
+	for i, wordlist := range wordlists {
+		docs[i] = textsearch.Document{
+			ID:          i,
+			Name:        wordlist.Name,
+			Description: wordlist.Info.Description,
+			Tags:        wordlist.Info.Tags,
+			SizeBytes:   wordlist.parsedSize,
+			Updated:     wordlist.parsedUpdated,
 		}
+		byID[i] = wordlist
+	}
+	return textsearch.Build(docs), byID
+}
+
+func searchRoutine(term string, wordlists []Wordlist) {
+	idx, byID := buildSearchIndex(wordlists)
+	query := textsearch.ParseQuery(term)
+
+	for _, result := range idx.Search(query) {
+		printInfo(byID[result.Document.ID])
 	}
 }
 
@@ -399,18 +511,58 @@ func fetchOne(wordlistArray []Wordlist, name string, basedir string) {
 	wordlistMap := convertWordlistToMap(wordlistArray)
 	result, ok := wordlistMap[name]
 	if ok {
-		downloadAndExtract(result.URL, path.Join(os.TempDir(), name), path.Join(basedir, result.Group))
+		if err := downloadAndExtract(result, path.Join(os.TempDir(), name), path.Join(basedir, result.Group)); err != nil {
+			report(err.Error())
+		}
 	} else {
 		report("No wordlist found with that name")
 	}
 }
 
-func fetchMulti(wordlistArray []Wordlist, group string, basedir string) {
+// fetchMulti fetches every wordlist of group through a bounded pool of jobs
+// workers, so that a whole category downloads in parallel instead of one
+// file at a time. Each job's error (if any) is collected and reported once
+// every download has finished, rather than aborting the rest of the group.
+func fetchMulti(wordlistArray []Wordlist, group string, basedir string, jobs int) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	var toFetch []Wordlist
 	for _, wordlist := range wordlistArray {
 		if wordlist.Info.Group == group {
-			downloadAndExtract(wordlist.Info.URL, path.Join(os.TempDir(), wordlist.Name), path.Join(basedir, wordlist.Info.Group))
+			toFetch = append(toFetch, wordlist)
 		}
 	}
+
+	queue := make(chan Wordlist)
+	errs := make(chan error, len(toFetch))
+	var wg sync.WaitGroup
+
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for wordlist := range queue {
+				downloadPath := path.Join(os.TempDir(), wordlist.Name)
+				finalPath := path.Join(basedir, wordlist.Info.Group)
+				if err := downloadAndExtract(wordlist.Info, downloadPath, finalPath); err != nil {
+					errs <- fmt.Errorf("%s: %w", wordlist.Name, err)
+				}
+			}
+		}()
+	}
+
+	for _, wordlist := range toFetch {
+		queue <- wordlist
+	}
+	close(queue)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		report(err.Error())
+	}
 }
 
 func listRoutine(wordlistArray []Wordlist, group string) {